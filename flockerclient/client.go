@@ -0,0 +1,251 @@
+// Package flockerclient is a typed Go client for the Flocker control
+// service REST API.
+package flockerclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// DefaultTimeout is the per-request deadline applied when Client.Timeout
+// is zero.
+const DefaultTimeout = 30 * time.Second
+
+// Client talks to a single Flocker control service over HTTPS.
+type Client struct {
+	URL    string
+	Client *http.Client
+
+	// Timeout bounds each individual request. It defaults to
+	// DefaultTimeout when zero.
+	Timeout time.Duration
+
+	// Logger receives Debugf/Infof/Warnf/Errorf calls for failed
+	// requests, non-2xx responses, and retry attempts. It defaults to
+	// a standard-log-backed logger at LevelInfo when nil. Errors from
+	// loading TLS material (LoadTLSConfig, Config.Client) are returned
+	// to the caller and are not logged.
+	Logger Logger
+
+	// RetryPolicy governs retries of transient failures across every
+	// API call. It defaults to DefaultRetryPolicy when nil.
+	RetryPolicy *RetryPolicy
+}
+
+// New builds a Client for the control service at controlService:port,
+// authenticating with the given TLS configuration. Every request made
+// through the returned Client is retried according to
+// Client.RetryPolicy.
+func New(controlService string, port int, tlsConfig *tls.Config) *Client {
+	c := &Client{
+		URL:         fmt.Sprintf("https://%s:%d", controlService, port),
+		Timeout:     DefaultTimeout,
+		Logger:      NewLogger(LevelInfo),
+		RetryPolicy: DefaultRetryPolicy(),
+	}
+
+	transport := &http.Transport{TLSClientConfig: tlsConfig}
+	c.Client = &http.Client{
+		Transport: withRetry(transport, func() *RetryPolicy { return c.RetryPolicy }, c.logger),
+	}
+	return c
+}
+
+// logger returns the configured Logger, falling back to a default
+// standard-log-backed logger when unset.
+func (c *Client) logger() Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	return NewLogger(LevelInfo)
+}
+
+// timeout returns the configured per-request timeout, falling back to
+// DefaultTimeout when unset.
+func (c *Client) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return DefaultTimeout
+}
+
+// get issues a GET request against path and decodes the JSON response
+// body into out.
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.URL+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		c.logger().Errorf("GET %s: %v", path, err)
+		return err
+	}
+	return c.decodeResponse(resp, out)
+}
+
+// post issues a POST request against path with body marshaled as JSON
+// and decodes the JSON response into out.
+func (c *Client) post(ctx context.Context, path string, body, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout())
+	defer cancel()
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.URL+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		c.logger().Errorf("POST %s: %v", path, err)
+		return err
+	}
+	return c.decodeResponse(resp, out)
+}
+
+// delete issues a DELETE request against path and decodes the JSON
+// response into out.
+func (c *Client) delete(ctx context.Context, path string, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", c.URL+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		c.logger().Errorf("DELETE %s: %v", path, err)
+		return err
+	}
+	return c.decodeResponse(resp, out)
+}
+
+// decodeResponse reads resp's body, returning an *APIError for non-2xx
+// status codes and otherwise decoding the body as JSON into out.
+func (c *Client) decodeResponse(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		if jsonErr := json.Unmarshal(data, apiErr); jsonErr != nil {
+			apiErr.Message = string(data)
+		}
+		c.logger().Warnf("request failed with status %d: %s", apiErr.StatusCode, apiErr.Message)
+		return apiErr
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// Version returns the control service's reported version string.
+func (c *Client) Version(ctx context.Context) (string, error) {
+	var v struct {
+		Version string `json:"flocker"`
+	}
+	if err := c.get(ctx, "/v1/version", &v); err != nil {
+		return "", err
+	}
+	return v.Version, nil
+}
+
+// ListDatasets returns the configured datasets known to the cluster.
+func (c *Client) ListDatasets(ctx context.Context) ([]Dataset, error) {
+	var datasets []Dataset
+	if err := c.get(ctx, "/v1/configuration/datasets", &datasets); err != nil {
+		return nil, err
+	}
+	return datasets, nil
+}
+
+// CreateDataset asks the control service to configure a new dataset.
+func (c *Client) CreateDataset(ctx context.Context, spec DatasetSpec) (Dataset, error) {
+	var dataset Dataset
+	if err := c.post(ctx, "/v1/configuration/datasets", spec, &dataset); err != nil {
+		return Dataset{}, err
+	}
+	return dataset, nil
+}
+
+// DeleteDataset deletes the dataset with the given id.
+func (c *Client) DeleteDataset(ctx context.Context, id string) error {
+	return c.delete(ctx, "/v1/configuration/datasets/"+id, nil)
+}
+
+// MoveDataset moves the dataset with the given id to a new primary node.
+func (c *Client) MoveDataset(ctx context.Context, id, primary string) (Dataset, error) {
+	var dataset Dataset
+	body := struct {
+		Primary string `json:"primary"`
+	}{Primary: primary}
+	if err := c.post(ctx, "/v1/configuration/datasets/"+id, body, &dataset); err != nil {
+		return Dataset{}, err
+	}
+	return dataset, nil
+}
+
+// ListNodes returns the nodes known to the cluster.
+func (c *Client) ListNodes(ctx context.Context) ([]Node, error) {
+	var nodes []Node
+	if err := c.get(ctx, "/v1/state/nodes", &nodes); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// ListContainers returns the containers running across the cluster.
+func (c *Client) ListContainers(ctx context.Context) ([]Container, error) {
+	var containers []Container
+	if err := c.get(ctx, "/v1/configuration/containers", &containers); err != nil {
+		return nil, err
+	}
+	return containers, nil
+}
+
+// WaitForDataset polls the cluster's dataset state (/v1/state/datasets)
+// until the dataset with id satisfies predicate, or ctx is cancelled.
+// It replaces the sleep-and-retry loops callers previously had to write
+// by hand, for example to wait for a dataset to show up on its new
+// primary after MoveDataset.
+func (c *Client) WaitForDataset(ctx context.Context, id string, predicate func(Dataset) bool) (Dataset, error) {
+	const pollInterval = 1 * time.Second
+
+	for {
+		var datasets []Dataset
+		if err := c.get(ctx, "/v1/state/datasets", &datasets); err != nil {
+			return Dataset{}, err
+		}
+		for _, dataset := range datasets {
+			if dataset.DatasetID == id && predicate(dataset) {
+				return dataset, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return Dataset{}, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package flockerclient
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// defaultConfigPath is where LoadConfig looks for a config file when
+// FLOCKER_CONFIG is unset.
+const defaultConfigPath = "~/.flocker/config.yaml"
+
+// ClusterConfig describes how to reach and authenticate against a
+// single Flocker cluster.
+type ClusterConfig struct {
+	ControlService     string `yaml:"control_service"`
+	ControlPort        int    `yaml:"control_port"`
+	KeyFile            string `yaml:"key_file"`
+	CertFile           string `yaml:"cert_file"`
+	CAFile             string `yaml:"ca_file"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+// Config is the top-level ~/.flocker/config.yaml document: a set of
+// named clusters.
+type Config struct {
+	Clusters map[string]ClusterConfig `yaml:"clusters"`
+}
+
+// defaultConfig mirrors the hardcoded envMap that main.go used before
+// config files existed, so callers who have neither a config file nor
+// environment overrides still get a working default cluster.
+func defaultConfig() *Config {
+	return &Config{
+		Clusters: map[string]ClusterConfig{
+			"default": {
+				ControlService: "54.157.8.57",
+				ControlPort:    4523,
+				KeyFile:        "/Users/kai/projects/flocker-api-examples/flockerdemo.key",
+				CertFile:       "/Users/kai/projects/flocker-api-examples/flockerdemo.crt",
+				CAFile:         "/Users/kai/projects/flocker-api-examples/cluster.crt",
+			},
+		},
+	}
+}
+
+// LoadConfig reads the cluster config file at path. If path is empty,
+// the FLOCKER_CONFIG environment variable is used, falling back to
+// ~/.flocker/config.yaml. A missing file is not an error: LoadConfig
+// returns defaultConfig so the tool remains usable out of the box.
+func LoadConfig(path string) (*Config, error) {
+	if path == "" {
+		path = os.Getenv("FLOCKER_CONFIG")
+	}
+	if path == "" {
+		path = defaultConfigPath
+	}
+	path, err := expandUser(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return applyEnvOverrides(defaultConfig()), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading config %s: %w", path, err)
+	}
+
+	config := &Config{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	return applyEnvOverrides(config), nil
+}
+
+// applyEnvOverrides preserves the precedence the tool has always had:
+// CONTROL_SERVICE/CONTROL_PORT/KEY_FILE/CERT_FILE/CA_FILE, when set,
+// override the "default" cluster's values.
+func applyEnvOverrides(config *Config) *Config {
+	cluster, ok := config.Clusters["default"]
+	if !ok {
+		cluster = ClusterConfig{}
+	}
+
+	if v := os.Getenv("CONTROL_SERVICE"); v != "" {
+		cluster.ControlService = v
+	}
+	if v := os.Getenv("CONTROL_PORT"); v != "" {
+		if port, err := strconv.Atoi(v); err == nil {
+			cluster.ControlPort = port
+		}
+	}
+	if v := os.Getenv("KEY_FILE"); v != "" {
+		cluster.KeyFile = v
+	}
+	if v := os.Getenv("CERT_FILE"); v != "" {
+		cluster.CertFile = v
+	}
+	if v := os.Getenv("CA_FILE"); v != "" {
+		cluster.CAFile = v
+	}
+
+	if config.Clusters == nil {
+		config.Clusters = map[string]ClusterConfig{}
+	}
+	config.Clusters["default"] = cluster
+	return config
+}
+
+// Client builds a TLS-enabled Client for the named cluster.
+func (c *Config) Client(clusterName string) (*Client, error) {
+	cluster, ok := c.Clusters[clusterName]
+	if !ok {
+		return nil, fmt.Errorf("no such cluster %q", clusterName)
+	}
+
+	tlsConfig, err := LoadTLSConfig(cluster.CertFile, cluster.KeyFile, cluster.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig.InsecureSkipVerify = cluster.InsecureSkipVerify
+
+	return New(cluster.ControlService, cluster.ControlPort, tlsConfig), nil
+}
+
+// expandUser expands a leading ~ in path to the current user's home
+// directory.
+func expandUser(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	if path == "~" {
+		return home, nil
+	}
+	return filepath.Join(home, path[2:]), nil
+}
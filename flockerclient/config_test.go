@@ -0,0 +1,124 @@
+package flockerclient
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func clearClusterEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{"CONTROL_SERVICE", "CONTROL_PORT", "KEY_FILE", "CERT_FILE", "CA_FILE"} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestLoadConfigMissingFileReturnsDefaults(t *testing.T) {
+	clearClusterEnv(t)
+
+	config, err := LoadConfig(filepath.Join(t.TempDir(), "does-not-exist.yaml"))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	cluster, ok := config.Clusters["default"]
+	if !ok {
+		t.Fatal("expected a default cluster")
+	}
+	if cluster.ControlService != "54.157.8.57" {
+		t.Errorf("ControlService = %q, want the built-in default", cluster.ControlService)
+	}
+}
+
+func TestLoadConfigParsesNamedClusters(t *testing.T) {
+	clearClusterEnv(t)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	contents := `
+clusters:
+  staging:
+    control_service: staging.example.com
+    control_port: 4523
+    key_file: /etc/flocker/staging.key
+    cert_file: /etc/flocker/staging.crt
+    ca_file: /etc/flocker/staging-ca.crt
+`
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	cluster, ok := config.Clusters["staging"]
+	if !ok {
+		t.Fatal("expected a staging cluster")
+	}
+	if cluster.ControlService != "staging.example.com" || cluster.ControlPort != 4523 {
+		t.Errorf("unexpected cluster: %+v", cluster)
+	}
+}
+
+func TestLoadConfigEnvOverridesTakePrecedence(t *testing.T) {
+	clearClusterEnv(t)
+	t.Setenv("CONTROL_SERVICE", "override.example.com")
+	t.Setenv("CONTROL_PORT", "9999")
+
+	config, err := LoadConfig(filepath.Join(t.TempDir(), "does-not-exist.yaml"))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	cluster := config.Clusters["default"]
+	if cluster.ControlService != "override.example.com" {
+		t.Errorf("ControlService = %q, want env override", cluster.ControlService)
+	}
+	if cluster.ControlPort != 9999 {
+		t.Errorf("ControlPort = %d, want env override", cluster.ControlPort)
+	}
+	// KeyFile wasn't overridden, so it should still carry the default.
+	if cluster.KeyFile == "" {
+		t.Error("expected KeyFile to retain its default value")
+	}
+}
+
+func TestLoadConfigEnvOverridesMergeWithFile(t *testing.T) {
+	clearClusterEnv(t)
+	t.Setenv("CONTROL_PORT", "1234")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	contents := `
+clusters:
+  default:
+    control_service: from-file.example.com
+    control_port: 4523
+    key_file: /etc/flocker/key
+    cert_file: /etc/flocker/cert
+    ca_file: /etc/flocker/ca
+`
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	cluster := config.Clusters["default"]
+	if cluster.ControlService != "from-file.example.com" {
+		t.Errorf("ControlService = %q, want the file's value untouched", cluster.ControlService)
+	}
+	if cluster.ControlPort != 1234 {
+		t.Errorf("ControlPort = %d, want the env override", cluster.ControlPort)
+	}
+}
+
+func TestConfigClientUnknownCluster(t *testing.T) {
+	config := &Config{Clusters: map[string]ClusterConfig{}}
+	if _, err := config.Client("missing"); err == nil {
+		t.Error("expected an error for an unknown cluster")
+	}
+}
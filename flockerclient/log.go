@@ -0,0 +1,89 @@
+package flockerclient
+
+import (
+	"log"
+	"strings"
+)
+
+// Level is a logger verbosity level.
+type Level int
+
+// Logger verbosity levels, from most to least verbose.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// ParseLevel parses a case-insensitive level name ("debug", "info",
+// "warn", "error") into a Level. It defaults to LevelInfo for unknown
+// input.
+func ParseLevel(s string) Level {
+	switch strings.ToLower(s) {
+	case "debug":
+		return LevelDebug
+	case "warn", "warning":
+		return LevelWarn
+	case "error":
+		return LevelError
+	default:
+		return LevelInfo
+	}
+}
+
+// Logger is the logging interface Client uses to report failed
+// requests, non-2xx responses, and retry attempts. Implementations are
+// expected to be safe for concurrent use.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// defaultLogger is a Logger backed by the standard log package, filtered
+// by a minimum Level.
+type defaultLogger struct {
+	level Level
+}
+
+// NewLogger returns a Logger backed by the standard log package that
+// discards messages below level.
+func NewLogger(level Level) Logger {
+	return &defaultLogger{level: level}
+}
+
+func (l *defaultLogger) log(level Level, prefix, format string, args ...interface{}) {
+	if level < l.level {
+		return
+	}
+	log.Printf(prefix+": "+format, args...)
+}
+
+func (l *defaultLogger) Debugf(format string, args ...interface{}) {
+	l.log(LevelDebug, "DEBUG", format, args...)
+}
+
+func (l *defaultLogger) Infof(format string, args ...interface{}) {
+	l.log(LevelInfo, "INFO", format, args...)
+}
+
+func (l *defaultLogger) Warnf(format string, args ...interface{}) {
+	l.log(LevelWarn, "WARN", format, args...)
+}
+
+func (l *defaultLogger) Errorf(format string, args ...interface{}) {
+	l.log(LevelError, "ERROR", format, args...)
+}
+
+// quietLogger is a Logger that discards every message.
+type quietLogger struct{}
+
+// Quiet is a Logger that discards all messages.
+var Quiet Logger = quietLogger{}
+
+func (quietLogger) Debugf(format string, args ...interface{}) {}
+func (quietLogger) Infof(format string, args ...interface{})  {}
+func (quietLogger) Warnf(format string, args ...interface{})  {}
+func (quietLogger) Errorf(format string, args ...interface{}) {}
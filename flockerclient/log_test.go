@@ -0,0 +1,66 @@
+package flockerclient
+
+import (
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	cases := map[string]Level{
+		"debug":   LevelDebug,
+		"DEBUG":   LevelDebug,
+		"info":    LevelInfo,
+		"":        LevelInfo,
+		"warn":    LevelWarn,
+		"warning": LevelWarn,
+		"error":   LevelError,
+		"bogus":   LevelInfo,
+	}
+	for input, want := range cases {
+		if got := ParseLevel(input); got != want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func captureLog(t *testing.T, fn func()) string {
+	t.Helper()
+	var buf strings.Builder
+	orig := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(orig)
+	fn()
+	return buf.String()
+}
+
+func TestDefaultLoggerFiltersBelowLevel(t *testing.T) {
+	logger := NewLogger(LevelWarn)
+
+	out := captureLog(t, func() {
+		logger.Debugf("debug message")
+		logger.Infof("info message")
+		logger.Warnf("warn message")
+		logger.Errorf("error message")
+	})
+
+	if strings.Contains(out, "debug message") || strings.Contains(out, "info message") {
+		t.Errorf("expected debug/info to be filtered out, got: %q", out)
+	}
+	if !strings.Contains(out, "warn message") || !strings.Contains(out, "error message") {
+		t.Errorf("expected warn/error to be logged, got: %q", out)
+	}
+}
+
+func TestQuietLoggerDiscardsEverything(t *testing.T) {
+	out := captureLog(t, func() {
+		Quiet.Debugf("debug message")
+		Quiet.Infof("info message")
+		Quiet.Warnf("warn message")
+		Quiet.Errorf("error message")
+	})
+
+	if out != "" {
+		t.Errorf("expected Quiet to log nothing, got: %q", out)
+	}
+}
@@ -0,0 +1,165 @@
+package flockerclient
+
+import (
+	"net/http"
+	"time"
+)
+
+// RetryPolicy configures how a Client retries requests that fail with a
+// transient error, such as those seen during control service leader
+// elections and dataset convergence.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// A value <= 1 disables retries.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between retries.
+	MaxBackoff time.Duration
+
+	// Multiplier scales the backoff after each attempt.
+	Multiplier float64
+
+	// ShouldRetry decides whether a response/error pair is worth
+	// retrying. It defaults to DefaultShouldRetry when nil.
+	ShouldRetry func(resp *http.Response, err error) bool
+}
+
+// DefaultRetryPolicy retries network errors, 502/503/504, and 409
+// Conflict on idempotent verbs, up to 4 attempts with exponential backoff starting at
+// 500ms and capping at 10s.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+		ShouldRetry:    DefaultShouldRetry,
+	}
+}
+
+// DefaultShouldRetry retries on network errors (err != nil), 502/503/504
+// gateway errors, and 409 Conflict for idempotent verbs only (GET,
+// HEAD, PUT, DELETE, OPTIONS, TRACE) — the control service returns 409
+// for configuration changes that raced a concurrent update, but a
+// non-idempotent POST (e.g. CreateDataset, MoveDataset) must not be
+// blindly resent on conflict.
+func DefaultShouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	case http.StatusConflict:
+		return isIdempotent(resp.Request.Method)
+	default:
+		return false
+	}
+}
+
+// isIdempotent reports whether method is safe to retry without risking
+// a duplicate side effect.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *RetryPolicy) shouldRetry(resp *http.Response, err error) bool {
+	if p.ShouldRetry != nil {
+		return p.ShouldRetry(resp, err)
+	}
+	return DefaultShouldRetry(resp, err)
+}
+
+// backoff returns the delay before the given retry attempt (0-indexed:
+// 0 is the delay before the first retry), capped at MaxBackoff.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	if delay > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	for i := 0; i < attempt; i++ {
+		delay = time.Duration(float64(delay) * p.Multiplier)
+		if delay > p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// retryRoundTripper wraps an http.RoundTripper, retrying requests that
+// the RetryPolicy classifies as transient. Retries honor the request's
+// context, sleeping between attempts only until the context is
+// cancelled.
+//
+// policy and logger are funcs rather than plain fields so that the
+// round tripper, which is installed once when the Client is
+// constructed, keeps picking up changes to Client.RetryPolicy and
+// Client.Logger made afterwards.
+type retryRoundTripper struct {
+	next   http.RoundTripper
+	policy func() *RetryPolicy
+	logger func() Logger
+}
+
+func (rt *retryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	policy := rt.policy()
+	if policy == nil {
+		policy = DefaultRetryPolicy()
+	}
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var resp *http.Response
+	var err error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt > 0 && req.Body != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, bodyErr
+			}
+			req.Body.Close()
+			req.Body = body
+		}
+
+		resp, err = rt.next.RoundTrip(req)
+		if attempt == maxAttempts-1 || !policy.shouldRetry(resp, err) {
+			return resp, err
+		}
+		if req.Body != nil && req.GetBody == nil {
+			// The body can't be rewound (it wasn't built from a
+			// type http.NewRequest knows how to snapshot), so
+			// resending it would corrupt the request. Give up with
+			// this attempt's response, its body still unread.
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		delay := policy.backoff(attempt)
+		rt.logger().Debugf("retrying %s %s after %v (attempt %d/%d)", req.Method, req.URL, delay, attempt+1, maxAttempts)
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+	}
+	return resp, err
+}
+
+// withRetry wraps transport with a retryRoundTripper that reads its
+// policy and logger from the supplied funcs on every request.
+func withRetry(transport http.RoundTripper, policy func() *RetryPolicy, logger func() Logger) http.RoundTripper {
+	return &retryRoundTripper{next: transport, policy: policy, logger: logger}
+}
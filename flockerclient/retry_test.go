@@ -0,0 +1,257 @@
+package flockerclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// fakeTransport replays one *http.Response (or error) per call from
+// responses, in order, recording the body each request actually sent.
+type fakeTransport struct {
+	responses []fakeResponse
+	calls     int
+	bodies    []string
+}
+
+type fakeResponse struct {
+	status int
+	err    error
+}
+
+func (f *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		data, _ := io.ReadAll(req.Body)
+		f.bodies = append(f.bodies, string(data))
+	} else {
+		f.bodies = append(f.bodies, "")
+	}
+
+	r := f.responses[f.calls]
+	f.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	resp := &http.Response{
+		StatusCode: r.status,
+		Body:       io.NopCloser(bytes.NewReader(nil)),
+		Request:    req,
+	}
+	return resp, nil
+}
+
+func fastPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		Multiplier:     2,
+	}
+}
+
+func newRetryTripper(transport http.RoundTripper, policy *RetryPolicy) http.RoundTripper {
+	return withRetry(transport, func() *RetryPolicy { return policy }, func() Logger { return Quiet })
+}
+
+func TestRetryRoundTripperRewindsBodyOnRetry(t *testing.T) {
+	transport := &fakeTransport{responses: []fakeResponse{
+		{status: http.StatusServiceUnavailable},
+		{status: http.StatusServiceUnavailable},
+		{status: http.StatusOK},
+	}}
+	rt := newRetryTripper(transport, fastPolicy())
+
+	req, err := http.NewRequest(http.MethodPost, "https://example.invalid/v1/configuration/datasets", bytes.NewReader([]byte(`{"hello":"world"}`)))
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("final status = %d, want 200", resp.StatusCode)
+	}
+	if transport.calls != 3 {
+		t.Fatalf("calls = %d, want 3", transport.calls)
+	}
+	for i, body := range transport.bodies {
+		if body != `{"hello":"world"}` {
+			t.Errorf("attempt %d body = %q, want full JSON body", i, body)
+		}
+	}
+}
+
+func TestRetryRoundTripperGivesUpWhenBodyNotRewindable(t *testing.T) {
+	transport := &fakeTransport{responses: []fakeResponse{
+		{status: http.StatusServiceUnavailable},
+		{status: http.StatusOK},
+	}}
+	rt := newRetryTripper(transport, fastPolicy())
+
+	req, err := http.NewRequest(http.MethodPost, "https://example.invalid/v1/configuration/datasets", bytes.NewReader([]byte(`{}`)))
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	// Simulate a body http.NewRequest couldn't snapshot (e.g. a plain
+	// io.Reader with no GetBody).
+	req.GetBody = nil
+
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want the first attempt's 503 (no retry attempted)", resp.StatusCode)
+	}
+	if transport.calls != 1 {
+		t.Errorf("calls = %d, want 1 (retry must not resend an unrewindable body)", transport.calls)
+	}
+}
+
+func TestRetryRoundTripperConflictOnlyRetriesIdempotentVerbs(t *testing.T) {
+	t.Run("POST is not retried", func(t *testing.T) {
+		transport := &fakeTransport{responses: []fakeResponse{
+			{status: http.StatusConflict},
+			{status: http.StatusOK},
+		}}
+		rt := newRetryTripper(transport, fastPolicy())
+
+		req, _ := http.NewRequest(http.MethodPost, "https://example.invalid/v1/configuration/datasets", bytes.NewReader([]byte(`{}`)))
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("RoundTrip: %v", err)
+		}
+		if resp.StatusCode != http.StatusConflict || transport.calls != 1 {
+			t.Errorf("status = %d, calls = %d, want 409 after exactly 1 call", resp.StatusCode, transport.calls)
+		}
+	})
+
+	t.Run("GET is retried", func(t *testing.T) {
+		transport := &fakeTransport{responses: []fakeResponse{
+			{status: http.StatusConflict},
+			{status: http.StatusOK},
+		}}
+		rt := newRetryTripper(transport, fastPolicy())
+
+		req, _ := http.NewRequest(http.MethodGet, "https://example.invalid/v1/configuration/datasets", nil)
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("RoundTrip: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK || transport.calls != 2 {
+			t.Errorf("status = %d, calls = %d, want 200 after 2 calls", resp.StatusCode, transport.calls)
+		}
+	})
+}
+
+func TestRetryRoundTripperRespectsContextCancellation(t *testing.T) {
+	transport := &fakeTransport{responses: []fakeResponse{
+		{status: http.StatusServiceUnavailable},
+		{status: http.StatusServiceUnavailable},
+		{status: http.StatusOK},
+	}}
+	policy := &RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: time.Hour,
+		MaxBackoff:     time.Hour,
+		Multiplier:     2,
+	}
+	rt := newRetryTripper(transport, policy)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.invalid/v1/version", nil)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := rt.RoundTrip(req)
+		done <- err
+	}()
+
+	// Let the first attempt happen, then cancel before the hour-long
+	// backoff would otherwise elapse.
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("RoundTrip did not return after context cancellation")
+	}
+}
+
+func TestRetryRoundTripperNetworkErrorIsRetried(t *testing.T) {
+	transport := &fakeTransport{responses: []fakeResponse{
+		{err: errors.New("connection reset")},
+		{status: http.StatusOK},
+	}}
+	rt := newRetryTripper(transport, fastPolicy())
+
+	req, _ := http.NewRequest(http.MethodGet, "https://example.invalid/v1/version", nil)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || transport.calls != 2 {
+		t.Errorf("status = %d, calls = %d, want 200 after 2 calls", resp.StatusCode, transport.calls)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := &RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     1 * time.Second,
+		Multiplier:     2,
+	}
+
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 100 * time.Millisecond},
+		{1, 200 * time.Millisecond},
+		{2, 400 * time.Millisecond},
+		{3, 800 * time.Millisecond},
+		{4, 1 * time.Second}, // capped
+		{10, 1 * time.Second},
+	}
+	for _, c := range cases {
+		if got := policy.backoff(c.attempt); got != c.want {
+			t.Errorf("backoff(%d) = %v, want %v", c.attempt, got, c.want)
+		}
+	}
+}
+
+func TestDefaultShouldRetry(t *testing.T) {
+	get, _ := http.NewRequest(http.MethodGet, "https://example.invalid", nil)
+	post, _ := http.NewRequest(http.MethodPost, "https://example.invalid", nil)
+
+	cases := []struct {
+		name  string
+		resp  *http.Response
+		err   error
+		retry bool
+	}{
+		{"network error", nil, errors.New("boom"), true},
+		{"502", &http.Response{StatusCode: http.StatusBadGateway, Request: get}, nil, true},
+		{"503", &http.Response{StatusCode: http.StatusServiceUnavailable, Request: post}, nil, true},
+		{"504", &http.Response{StatusCode: http.StatusGatewayTimeout, Request: post}, nil, true},
+		{"409 GET", &http.Response{StatusCode: http.StatusConflict, Request: get}, nil, true},
+		{"409 POST", &http.Response{StatusCode: http.StatusConflict, Request: post}, nil, false},
+		{"200", &http.Response{StatusCode: http.StatusOK, Request: get}, nil, false},
+		{"404", &http.Response{StatusCode: http.StatusNotFound, Request: get}, nil, false},
+	}
+	for _, c := range cases {
+		if got := DefaultShouldRetry(c.resp, c.err); got != c.retry {
+			t.Errorf("%s: DefaultShouldRetry = %v, want %v", c.name, got, c.retry)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package flockerclient
+
+import "github.com/google/uuid"
+
+// Dataset is a Flocker dataset as returned by the control service.
+type Dataset struct {
+	DatasetID   string            `json:"dataset_id"`
+	Primary     uuid.UUID         `json:"primary"`
+	MaximumSize int64             `json:"maximum_size,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Deleted     bool              `json:"deleted,omitempty"`
+}
+
+// DatasetSpec describes the dataset a caller wants created.
+type DatasetSpec struct {
+	Primary     uuid.UUID         `json:"primary"`
+	MaximumSize int64             `json:"maximum_size,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// Node is a node in the cluster, as reported by the control service.
+type Node struct {
+	UUID uuid.UUID `json:"uuid"`
+	Host string    `json:"host"`
+}
+
+// Container is a container running on a node, as reported by the control service.
+type Container struct {
+	Name  string `json:"name"`
+	Node  string `json:"node_uuid"`
+	Image string `json:"image"`
+}
+
+// APIError is the JSON body the control service returns alongside a
+// non-2xx status code.
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Message    string `json:"description"`
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}